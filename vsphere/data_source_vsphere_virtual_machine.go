@@ -3,11 +3,16 @@ package vsphere
 import (
 	"fmt"
 	"log"
+	"net"
+	"time"
 
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/helper/virtualmachine"
 	"github.com/terraform-providers/terraform-provider-vsphere/vsphere/internal/virtualdevice"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 func dataSourceVSphereVirtualMachine() *schema.Resource {
@@ -92,6 +97,42 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 							Type:     schema.TypeBool,
 							Computed: true,
 						},
+						"uuid": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"datastore_id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"path": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"label": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"unit_number": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"controller_type": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"write_through": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"disk_mode": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"sharing": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
 					},
 				},
 			},
@@ -113,6 +154,11 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 							Type:     schema.TypeString,
 							Computed: true,
 						},
+						"ip_addresses": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
 					},
 				},
 			},
@@ -122,6 +168,59 @@ func dataSourceVSphereVirtualMachine() *schema.Resource {
 				Computed:    true,
 				Elem:        &schema.Schema{Type: schema.TypeString},
 			},
+			"default_ip_address": {
+				Type:        schema.TypeString,
+				Description: "The IP address selected by VMware Tools to be reported as the virtual machine's primary IP address.",
+				Computed:    true,
+			},
+			"guest_ip_addresses": {
+				Type:        schema.TypeList,
+				Description: "The current list of IP addresses on this machine, as reported by VMware Tools across all network interfaces.",
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"power_state": {
+				Type:        schema.TypeString,
+				Description: "The current power state of the virtual machine.",
+				Computed:    true,
+			},
+			"tools_status": {
+				Type:        schema.TypeString,
+				Description: "The state of VMware Tools in the guest. This will determine the proper course of action for some operations.",
+				Computed:    true,
+			},
+			"tools_running_status": {
+				Type:        schema.TypeString,
+				Description: "The run state of VMware Tools in the guest.",
+				Computed:    true,
+			},
+			"hostname": {
+				Type:        schema.TypeString,
+				Description: "The hostname reported by VMware Tools in the guest.",
+				Computed:    true,
+			},
+			"wait_for_guest_ip_timeout": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "The amount of time, in minutes, to wait for the VM's guest to report a non-link-local IP address. Set to 0 to not wait for an IP.",
+			},
+			"custom_attributes": {
+				Type:        schema.TypeMap,
+				Description: "A map of custom attribute name/value pairs set on this virtual machine.",
+				Computed:    true,
+			},
+			"extra_config": {
+				Type:        schema.TypeMap,
+				Description: "A map of advanced configuration parameters (extraConfig) set on this virtual machine, filtered by extra_config_keys if set.",
+				Computed:    true,
+			},
+			"extra_config_keys": {
+				Type:        schema.TypeList,
+				Description: "Allows filtering the extra_config map to only include keys in this list.",
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
 		},
 	}
 }
@@ -157,6 +256,13 @@ func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{
 		return fmt.Errorf("virtual machine %q does not have a UUID", vm.InventoryPath)
 	}
 
+	if timeout := d.Get("wait_for_guest_ip_timeout").(int); timeout > 0 {
+		props, err = waitForGuestIP(vm, timeout)
+		if err != nil {
+			return err
+		}
+	}
+
 	d.SetId(props.Config.Uuid)
 	d.Set("guest_id", props.Config.GuestId)
 	d.Set("alternate_guest_name", props.Config.AlternateGuestName)
@@ -185,9 +291,187 @@ func dataSourceVSphereVirtualMachineRead(d *schema.ResourceData, meta interface{
 	if d.Set("network_interface_types", nics); err != nil {
 		return fmt.Errorf("error setting network interface types: %s", err)
 	}
+	flattenGuestNetInfo(networkInterfaces, props.Guest)
 	if d.Set("network_interfaces", networkInterfaces); err != nil {
 		return fmt.Errorf("error setting network interfaces: %s", err)
 	}
+
+	d.Set("power_state", string(props.Runtime.PowerState))
+	if props.Guest != nil {
+		d.Set("default_ip_address", props.Guest.IpAddress)
+		d.Set("hostname", props.Guest.HostName)
+		d.Set("tools_status", string(props.Guest.ToolsStatus))
+		d.Set("tools_running_status", props.Guest.ToolsRunningStatus)
+		if err := d.Set("guest_ip_addresses", guestIPAddresses(props.Guest)); err != nil {
+			return fmt.Errorf("error setting guest IP addresses: %s", err)
+		}
+	}
+
+	customAttrs := readCustomAttributes(props.AvailableField, props.CustomValue)
+	if err := d.Set("custom_attributes", customAttrs); err != nil {
+		return fmt.Errorf("error setting custom attributes: %s", err)
+	}
+
+	extraConfigKeys := sliceInterfacesToStrings(d.Get("extra_config_keys").([]interface{}))
+	if err := d.Set("extra_config", readExtraConfig(props.Config.ExtraConfig, extraConfigKeys)); err != nil {
+		return fmt.Errorf("error setting extra config: %s", err)
+	}
+
 	log.Printf("[DEBUG] VM search for %q completed successfully (UUID %q)", name, props.Config.Uuid)
 	return nil
 }
+
+// readCustomAttributes resolves the custom field keys reported on a virtual
+// machine's CustomValue property to their human-readable names using the
+// same properties already returned by virtualmachine.Properties, avoiding a
+// separate CustomFieldsManager round trip.
+func readCustomAttributes(availableFields []types.CustomFieldDef, customValues []types.BaseCustomFieldValue) map[string]string {
+	if len(customValues) == 0 {
+		return nil
+	}
+	return flattenCustomAttributes(customValues, customAttributeNamesByKey(availableFields))
+}
+
+// customAttributeNamesByKey maps a virtual machine's AvailableField entries
+// (the custom attribute definitions visible to the current session) by
+// their key. Shared by both the singular and plural virtual machine data
+// sources.
+func customAttributeNamesByKey(availableFields []types.CustomFieldDef) map[int32]string {
+	names := make(map[int32]string, len(availableFields))
+	for _, f := range availableFields {
+		names[f.Key] = f.Name
+	}
+	return names
+}
+
+// flattenCustomAttributes resolves a virtual machine's CustomValue entries
+// to a name/value map using the supplied key-to-name lookup, as produced by
+// customAttributeNamesByKey.
+func flattenCustomAttributes(customValues []types.BaseCustomFieldValue, names map[int32]string) map[string]string {
+	if len(customValues) == 0 {
+		return nil
+	}
+	attrs := make(map[string]string)
+	for _, cv := range customValues {
+		v, ok := cv.(*types.CustomFieldStringValue)
+		if !ok {
+			continue
+		}
+		if name, ok := names[v.Key]; ok {
+			attrs[name] = v.Value
+		}
+	}
+	return attrs
+}
+
+// readExtraConfig flattens a virtual machine's extraConfig key/value pairs
+// into a string map, optionally restricting the result to the supplied
+// keys to avoid surfacing the hundreds of VMware-internal entries most
+// virtual machines carry.
+func readExtraConfig(extraConfig []types.BaseOptionValue, keys []string) map[string]string {
+	var filter map[string]struct{}
+	if len(keys) > 0 {
+		filter = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			filter[k] = struct{}{}
+		}
+	}
+	m := make(map[string]string)
+	for _, bov := range extraConfig {
+		ov := bov.GetOptionValue()
+		if filter != nil {
+			if _, ok := filter[ov.Key]; !ok {
+				continue
+			}
+		}
+		if s, ok := ov.Value.(string); ok {
+			m[ov.Key] = s
+		}
+	}
+	return m
+}
+
+// sliceInterfacesToStrings converts a []interface{} sourced from a
+// TypeList of TypeString into a []string.
+func sliceInterfacesToStrings(in []interface{}) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, v := range in {
+		out[i] = v.(string)
+	}
+	return out
+}
+
+// guestIPAddresses flattens the IP addresses reported by VMware Tools across
+// all of a virtual machine's network interfaces, discarding link-local
+// addresses that are not useful to consumers of the data source.
+func guestIPAddresses(guest *types.GuestInfo) []string {
+	if guest == nil {
+		return nil
+	}
+	var ips []string
+	for _, nic := range guest.Net {
+		for _, ip := range nic.IpAddress {
+			if isLinkLocalIPAddress(ip) {
+				continue
+			}
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// flattenGuestNetInfo adds the IP addresses reported by VMware Tools for each
+// NIC, matched up by MAC address, to the already-flattened network interface
+// list produced by virtualdevice.ReadNetworkInterfaces.
+func flattenGuestNetInfo(networkInterfaces []map[string]interface{}, guest *types.GuestInfo) {
+	if guest == nil {
+		return
+	}
+	ipsByMAC := make(map[string][]string)
+	for _, nic := range guest.Net {
+		ipsByMAC[nic.MacAddress] = nic.IpAddress
+	}
+	for _, ni := range networkInterfaces {
+		mac, _ := ni["mac_address"].(string)
+		ni["ip_addresses"] = ipsByMAC[mac]
+	}
+}
+
+// isLinkLocalIPAddress returns true if the supplied address string is an
+// IPv4 or IPv6 link-local address.
+func isLinkLocalIPAddress(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast())
+}
+
+// waitForGuestIP polls the virtual machine's properties until VMware Tools
+// reports at least one non-link-local IP address, or the timeout (in
+// minutes) elapses.
+func waitForGuestIP(vm *object.VirtualMachine, timeoutMinutes int) (*mo.VirtualMachine, error) {
+	var props *mo.VirtualMachine
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"pending"},
+		Target:  []string{"ok"},
+		Refresh: func() (interface{}, string, error) {
+			var err error
+			props, err = virtualmachine.Properties(vm)
+			if err != nil {
+				return nil, "", err
+			}
+			if len(guestIPAddresses(props.Guest)) > 0 {
+				return props, "ok", nil
+			}
+			return props, "pending", nil
+		},
+		Timeout:    time.Duration(timeoutMinutes) * time.Minute,
+		MinTimeout: 2 * time.Second,
+		Delay:      1 * time.Second,
+	}
+	if _, err := stateConf.WaitForState(); err != nil {
+		return nil, fmt.Errorf("error waiting for an available guest IP address: %s", err)
+	}
+	return props, nil
+}
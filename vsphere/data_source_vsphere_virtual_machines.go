@@ -0,0 +1,354 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func dataSourceVSphereVirtualMachines() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceVSphereVirtualMachinesRead,
+
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:        schema.TypeList,
+				Description: "A filter block to narrow down the virtual machines returned. When omitted, every virtual machine visible to the provider's credentials is returned.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"folder": {
+							Type:        schema.TypeString,
+							Description: "The relative path of the folder that matching virtual machines must reside in.",
+							Optional:    true,
+						},
+						"datacenter_id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the datacenter that matching virtual machines must reside in.",
+							Optional:    true,
+						},
+						"resource_pool_id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the resource pool that matching virtual machines must reside in.",
+							Optional:    true,
+						},
+						"cluster_id": {
+							Type:        schema.TypeString,
+							Description: "The managed object ID of the cluster that matching virtual machines must reside in.",
+							Optional:    true,
+						},
+						"guest_id_regex": {
+							Type:        schema.TypeString,
+							Description: "A regular expression matched against each virtual machine's guest_id.",
+							Optional:    true,
+						},
+						"name_regex": {
+							Type:        schema.TypeString,
+							Description: "A regular expression matched against each virtual machine's name.",
+							Optional:    true,
+						},
+						"power_state": {
+							Type:        schema.TypeString,
+							Description: "Only return virtual machines currently in this power state, one of poweredOn, poweredOff, or suspended.",
+							Optional:    true,
+						},
+						"custom_attribute": {
+							Type:        schema.TypeList,
+							Description: "Only return virtual machines that have the given custom attribute set to the given value. Can be specified multiple times.",
+							Optional:    true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:        schema.TypeString,
+										Description: "The name of the custom attribute to match.",
+										Required:    true,
+									},
+									"value": {
+										Type:        schema.TypeString,
+										Description: "The value the custom attribute must be set to.",
+										Required:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"virtual_machines": {
+				Type:        schema.TypeList,
+				Description: "The list of virtual machines matching the filter.",
+				Computed:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Description: "The UUID of the virtual machine.",
+							Computed:    true,
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Description: "The name of the virtual machine.",
+							Computed:    true,
+						},
+						"guest_id": {
+							Type:        schema.TypeString,
+							Description: "The guest ID of the virtual machine.",
+							Computed:    true,
+						},
+						"power_state": {
+							Type:        schema.TypeString,
+							Description: "The current power state of the virtual machine.",
+							Computed:    true,
+						},
+						"folder": {
+							Type:        schema.TypeString,
+							Description: "The relative path of the folder the virtual machine resides in.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// vmFilterSpec holds the parsed contents of the data source's filter block.
+type vmFilterSpec struct {
+	folder         string
+	datacenterID   string
+	resourcePoolID string
+	clusterID      string
+	powerState     string
+	guestIDRegex   *regexp.Regexp
+	nameRegex      *regexp.Regexp
+	customAttrs    map[string]string
+}
+
+func expandVMFilterSpec(d *schema.ResourceData) (*vmFilterSpec, error) {
+	spec := &vmFilterSpec{}
+	raw, ok := d.GetOk("filter")
+	if !ok {
+		return spec, nil
+	}
+	fl := raw.([]interface{})
+	if len(fl) == 0 || fl[0] == nil {
+		return spec, nil
+	}
+	f := fl[0].(map[string]interface{})
+	spec.folder = f["folder"].(string)
+	spec.datacenterID = f["datacenter_id"].(string)
+	spec.resourcePoolID = f["resource_pool_id"].(string)
+	spec.clusterID = f["cluster_id"].(string)
+	spec.powerState = f["power_state"].(string)
+
+	if v := f["guest_id_regex"].(string); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid guest_id_regex: %s", err)
+		}
+		spec.guestIDRegex = re
+	}
+	if v := f["name_regex"].(string); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid name_regex: %s", err)
+		}
+		spec.nameRegex = re
+	}
+	if attrs, ok := f["custom_attribute"].([]interface{}); ok && len(attrs) > 0 {
+		spec.customAttrs = make(map[string]string, len(attrs))
+		for _, rawAttr := range attrs {
+			ca := rawAttr.(map[string]interface{})
+			spec.customAttrs[ca["name"].(string)] = ca["value"].(string)
+		}
+	}
+	return spec, nil
+}
+
+// containerViewRoot returns the managed object that the container view
+// should be rooted at, preferring the most specific container supplied in
+// the filter so the server only has to enumerate the relevant subtree. Only
+// that one container is applied; the less specific container IDs in the
+// filter are not checked against the virtual machines found.
+func (spec *vmFilterSpec) containerViewRoot(root types.ManagedObjectReference) types.ManagedObjectReference {
+	switch {
+	case spec.resourcePoolID != "":
+		return types.ManagedObjectReference{Type: "ResourcePool", Value: spec.resourcePoolID}
+	case spec.clusterID != "":
+		return types.ManagedObjectReference{Type: "ClusterComputeResource", Value: spec.clusterID}
+	case spec.datacenterID != "":
+		return types.ManagedObjectReference{Type: "Datacenter", Value: spec.datacenterID}
+	default:
+		return root
+	}
+}
+
+func dataSourceVSphereVirtualMachinesRead(d *schema.ResourceData, meta interface{}) error {
+	client := meta.(*VSphereClient).vimClient
+	ctx := context.TODO()
+
+	spec, err := expandVMFilterSpec(d)
+	if err != nil {
+		return err
+	}
+
+	viewRoot := spec.containerViewRoot(client.ServiceContent.RootFolder)
+
+	m := view.NewManager(client.Client)
+	cv, err := m.CreateContainerView(ctx, viewRoot, []string{"VirtualMachine"}, true)
+	if err != nil {
+		return fmt.Errorf("error creating container view: %s", err)
+	}
+	defer func() {
+		_ = cv.Destroy(ctx)
+	}()
+
+	var vms []mo.VirtualMachine
+	err = cv.Retrieve(ctx, []string{"VirtualMachine"}, []string{
+		"name",
+		"parent",
+		"config.guestId",
+		"config.uuid",
+		"runtime.powerState",
+		"summary.config",
+		"customValue",
+		"availableField",
+	}, &vms)
+	if err != nil {
+		return fmt.Errorf("error retrieving virtual machines: %s", err)
+	}
+
+	var customAttrNames map[int32]string
+	if len(spec.customAttrs) > 0 && len(vms) > 0 {
+		// availableField lists every custom attribute definition visible to
+		// the current session, so it's the same regardless of which VM it's
+		// read from - reuse the first one instead of a separate
+		// CustomFieldsManager round trip.
+		customAttrNames = customAttributeNamesByKey(vms[0].AvailableField)
+	}
+
+	folders, err := loadFolderTree(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var out []map[string]interface{}
+	for _, vmMo := range vms {
+		if vmMo.Config == nil {
+			continue
+		}
+		folderPath := vmFolderPath(vmMo, folders)
+		if !vmMatchesFilter(spec, vmMo, folderPath, customAttrNames) {
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"id":          vmMo.Config.Uuid,
+			"name":        vmMo.Name,
+			"guest_id":    vmMo.Config.GuestId,
+			"power_state": string(vmMo.Runtime.PowerState),
+			"folder":      folderPath,
+		})
+	}
+
+	if err := d.Set("virtual_machines", out); err != nil {
+		return fmt.Errorf("error setting virtual_machines: %s", err)
+	}
+
+	d.SetId(time.Now().UTC().String())
+	return nil
+}
+
+// folderInfo is the subset of a Folder managed object needed to walk its
+// inventory path one parent at a time.
+type folderInfo struct {
+	name   string
+	parent *types.ManagedObjectReference
+}
+
+// loadFolderTree retrieves every folder in the inventory in a single
+// property collector call, so that each virtual machine's folder path can
+// be resolved from the in-memory result instead of a per-VM round trip.
+func loadFolderTree(ctx context.Context, client *govmomi.Client) (map[types.ManagedObjectReference]folderInfo, error) {
+	m := view.NewManager(client.Client)
+	fv, err := m.CreateContainerView(ctx, client.ServiceContent.RootFolder, []string{"Folder"}, true)
+	if err != nil {
+		return nil, fmt.Errorf("error creating folder container view: %s", err)
+	}
+	defer func() {
+		_ = fv.Destroy(ctx)
+	}()
+
+	var folders []mo.Folder
+	if err := fv.Retrieve(ctx, []string{"Folder"}, []string{"name", "parent"}, &folders); err != nil {
+		return nil, fmt.Errorf("error retrieving folders: %s", err)
+	}
+
+	tree := make(map[types.ManagedObjectReference]folderInfo, len(folders))
+	for _, f := range folders {
+		tree[f.Reference()] = folderInfo{name: f.Name, parent: f.Parent}
+	}
+	return tree, nil
+}
+
+// vmFolderPath resolves the relative path of the folder a virtual machine
+// resides in by walking up its chain of parent folders, stopping at the
+// datacenter's root "vm" folder so the result is relative to it (matching
+// the path accepted by the resource's folder attribute) rather than the
+// absolute inventory path.
+func vmFolderPath(vmMo mo.VirtualMachine, folders map[types.ManagedObjectReference]folderInfo) string {
+	if vmMo.Parent == nil {
+		return ""
+	}
+	var parts []string
+	ref := *vmMo.Parent
+	for ref.Type == "Folder" {
+		f, ok := folders[ref]
+		if !ok {
+			break
+		}
+		if f.name == "vm" && (f.parent == nil || f.parent.Type == "Datacenter") {
+			break
+		}
+		parts = append([]string{f.name}, parts...)
+		if f.parent == nil {
+			break
+		}
+		ref = *f.parent
+	}
+	return strings.Join(parts, "/")
+}
+
+// vmMatchesFilter evaluates a retrieved virtual machine against the parsed
+// filter spec, returning false on the first predicate it fails.
+func vmMatchesFilter(spec *vmFilterSpec, vmMo mo.VirtualMachine, folderPath string, customAttrNames map[int32]string) bool {
+	if spec.nameRegex != nil && !spec.nameRegex.MatchString(vmMo.Name) {
+		return false
+	}
+	if spec.guestIDRegex != nil && !spec.guestIDRegex.MatchString(vmMo.Config.GuestId) {
+		return false
+	}
+	if spec.powerState != "" && string(vmMo.Runtime.PowerState) != spec.powerState {
+		return false
+	}
+	if spec.folder != "" && folderPath != spec.folder {
+		return false
+	}
+	if len(spec.customAttrs) > 0 {
+		actual := flattenCustomAttributes(vmMo.CustomValue, customAttrNames)
+		for name, want := range spec.customAttrs {
+			if actual[name] != want {
+				return false
+			}
+		}
+	}
+	return true
+}
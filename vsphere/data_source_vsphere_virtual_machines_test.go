@@ -0,0 +1,84 @@
+package vsphere
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testMORef(kind, value string) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: kind, Value: value}
+}
+
+func TestVMFolderPath(t *testing.T) {
+	dcRef := testMORef("Datacenter", "datacenter-1")
+	vmRootFolderRef := testMORef("Folder", "group-v1")
+	subFolderRef := testMORef("Folder", "group-v2")
+
+	folders := map[types.ManagedObjectReference]folderInfo{
+		vmRootFolderRef: {name: "vm", parent: &dcRef},
+		subFolderRef:    {name: "my-folder", parent: &vmRootFolderRef},
+	}
+
+	cases := []struct {
+		name   string
+		parent *types.ManagedObjectReference
+		want   string
+	}{
+		{"nested folder", &subFolderRef, "my-folder"},
+		{"datacenter vm root folder", &vmRootFolderRef, ""},
+		{"no parent", nil, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vm := mo.VirtualMachine{}
+			vm.Parent = tc.parent
+			if got := vmFolderPath(vm, folders); got != tc.want {
+				t.Fatalf("expected folder path %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestVMMatchesFilter(t *testing.T) {
+	vm := mo.VirtualMachine{}
+	vm.Name = "test-vm"
+	vm.Config = &types.VirtualMachineConfigInfo{GuestId: "ubuntu64Guest"}
+	vm.Runtime.PowerState = types.VirtualMachinePoweredOn
+	vm.CustomValue = []types.BaseCustomFieldValue{
+		&types.CustomFieldStringValue{
+			CustomFieldValue: types.CustomFieldValue{Key: 100},
+			Value:            "prod",
+		},
+	}
+	customAttrNames := map[int32]string{100: "environment"}
+
+	cases := []struct {
+		name       string
+		spec       *vmFilterSpec
+		folderPath string
+		want       bool
+	}{
+		{"empty filter matches", &vmFilterSpec{}, "", true},
+		{"name_regex matches", &vmFilterSpec{nameRegex: regexp.MustCompile(`^test-`)}, "", true},
+		{"name_regex mismatches", &vmFilterSpec{nameRegex: regexp.MustCompile(`^prod-`)}, "", false},
+		{"guest_id_regex matches", &vmFilterSpec{guestIDRegex: regexp.MustCompile(`^ubuntu`)}, "", true},
+		{"power_state matches", &vmFilterSpec{powerState: "poweredOn"}, "", true},
+		{"power_state mismatches", &vmFilterSpec{powerState: "poweredOff"}, "", false},
+		{"folder matches", &vmFilterSpec{folder: "my-folder"}, "my-folder", true},
+		{"folder mismatches", &vmFilterSpec{folder: "other-folder"}, "my-folder", false},
+		{"custom_attribute matches", &vmFilterSpec{customAttrs: map[string]string{"environment": "prod"}}, "", true},
+		{"custom_attribute mismatches", &vmFilterSpec{customAttrs: map[string]string{"environment": "dev"}}, "", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := vmMatchesFilter(tc.spec, vm, tc.folderPath, customAttrNames); got != tc.want {
+				t.Fatalf("expected match=%t, got %t", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package virtualdevice
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// ReadDiskAttrsForDataSource returns a flattened list of disk attributes,
+// sorted by controller bus number and then unit number, for use as the
+// value of a data source's "disks" computed attribute. In addition to
+// size and provisioning flags, it surfaces the backing identity of each
+// disk (UUID, datastore, and VMDK path) so it can be used to import an
+// existing disk into a managed vsphere_virtual_machine resource. Disks on
+// SCSI controllers past scsiControllerScanCount are skipped, mirroring the
+// scan count honored by ReadSCSIBusType and ReadSCSIBusSharing.
+func ReadDiskAttrsForDataSource(l object.VirtualDeviceList, scsiControllerScanCount int) ([]map[string]interface{}, error) {
+	devices := l.SelectByType((*types.VirtualDisk)(nil))
+
+	type entry struct {
+		busNumber  int32
+		unitNumber int32
+		attrs      map[string]interface{}
+	}
+	var entries []entry
+	for _, dev := range devices {
+		disk, ok := dev.(*types.VirtualDisk)
+		if !ok {
+			continue
+		}
+		ctlrDevice := l.FindByKey(disk.ControllerKey)
+		if ctlrDevice == nil {
+			return nil, fmt.Errorf("could not find controller with key %d for disk %q", disk.ControllerKey, l.Name(disk))
+		}
+		ctlr, ok := ctlrDevice.(types.BaseVirtualController)
+		if !ok {
+			return nil, fmt.Errorf("controller with key %d for disk %q is not a recognized controller type", disk.ControllerKey, l.Name(disk))
+		}
+		busNumber := ctlr.GetVirtualController().BusNumber
+		controllerType := diskControllerType(ctlrDevice)
+		if isSCSIControllerType(controllerType) && int(busNumber) >= scsiControllerScanCount {
+			continue
+		}
+
+		var unitNumber int32
+		if disk.UnitNumber != nil {
+			unitNumber = *disk.UnitNumber
+		}
+
+		m := map[string]interface{}{
+			"size":            disk.CapacityInKB / 1024 / 1024,
+			"label":           l.Name(disk),
+			"unit_number":     int(unitNumber),
+			"controller_type": controllerType,
+		}
+
+		switch backing := disk.Backing.(type) {
+		case *types.VirtualDiskFlatVer2BackingInfo:
+			m["uuid"] = backing.Uuid
+			m["path"] = backing.FileName
+			m["disk_mode"] = backing.DiskMode
+			m["sharing"] = backing.Sharing
+			m["thin_provisioned"] = backing.ThinProvisioned != nil && *backing.ThinProvisioned
+			m["eagerly_scrub"] = backing.EagerlyScrub != nil && *backing.EagerlyScrub
+			m["write_through"] = backing.WriteThrough != nil && *backing.WriteThrough
+			if backing.Datastore != nil {
+				m["datastore_id"] = backing.Datastore.Value
+			}
+		}
+
+		entries = append(entries, entry{busNumber: busNumber, unitNumber: unitNumber, attrs: m})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].busNumber != entries[j].busNumber {
+			return entries[i].busNumber < entries[j].busNumber
+		}
+		return entries[i].unitNumber < entries[j].unitNumber
+	})
+
+	out := make([]map[string]interface{}, len(entries))
+	for i, e := range entries {
+		out[i] = e.attrs
+	}
+	return out, nil
+}
+
+// isSCSIControllerType returns true if the supplied controller_type value
+// identifies a SCSI bus, as opposed to SATA, IDE, or NVMe.
+func isSCSIControllerType(t string) bool {
+	switch t {
+	case "lsilogic", "lsilogic-sas", "buslogic", "pvscsi":
+		return true
+	default:
+		return false
+	}
+}
+
+// diskControllerType returns a short identifier for the type of controller
+// a disk device is attached to, matching the values accepted by the
+// vsphere_virtual_machine resource's disk controller_type attribute.
+func diskControllerType(ctlr types.BaseVirtualDevice) string {
+	switch ctlr.(type) {
+	case *types.VirtualLsiLogicController:
+		return "lsilogic"
+	case *types.VirtualLsiLogicSASController:
+		return "lsilogic-sas"
+	case *types.VirtualBusLogicController:
+		return "buslogic"
+	case *types.ParaVirtualSCSIController:
+		return "pvscsi"
+	case *types.VirtualIDEController:
+		return "ide"
+	case *types.VirtualAHCIController:
+		return "sata"
+	case *types.VirtualNVMEController:
+		return "nvme"
+	default:
+		return "unknown"
+	}
+}
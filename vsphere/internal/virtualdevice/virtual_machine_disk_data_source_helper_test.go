@@ -0,0 +1,138 @@
+package virtualdevice
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func testInt32Ptr(v int32) *int32 {
+	return &v
+}
+
+func testBoolPtr(v bool) *bool {
+	return &v
+}
+
+func testDisk(key int32, controllerKey int32, unitNumber int32, capacityKB int64, uuid string) *types.VirtualDisk {
+	return &types.VirtualDisk{
+		VirtualDevice: types.VirtualDevice{
+			Key:           key,
+			ControllerKey: controllerKey,
+			UnitNumber:    testInt32Ptr(unitNumber),
+			Backing: &types.VirtualDiskFlatVer2BackingInfo{
+				VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{
+					FileName:  "[datastore1] vm/disk.vmdk",
+					Datastore: &types.ManagedObjectReference{Type: "Datastore", Value: "datastore-100"},
+				},
+				Uuid:            uuid,
+				ThinProvisioned: testBoolPtr(true),
+			},
+		},
+		CapacityInKB: capacityKB,
+	}
+}
+
+func TestReadDiskAttrsForDataSource(t *testing.T) {
+	scsiBus0 := &types.VirtualLsiLogicController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1000},
+				BusNumber:     0,
+			},
+		},
+	}
+	scsiBus1 := &types.VirtualLsiLogicController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1001},
+				BusNumber:     1,
+			},
+		},
+	}
+	sataBus2 := &types.VirtualAHCIController{
+		VirtualSATAController: types.VirtualSATAController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1002},
+				BusNumber:     2,
+			},
+		},
+	}
+
+	// Intentionally out of bus/unit order to exercise the sort.
+	diskBus0Unit1 := testDisk(2000, 1000, 1, 10*1024*1024, "uuid-bus0-unit1")
+	diskBus0Unit0 := testDisk(2001, 1000, 0, 20*1024*1024, "uuid-bus0-unit0")
+	diskBus1Unit0 := testDisk(2002, 1001, 0, 5*1024*1024, "uuid-bus1-unit0-excluded")
+	diskBus2Unit0 := testDisk(2003, 1002, 0, 15*1024*1024, "uuid-bus2-unit0")
+
+	l := object.VirtualDeviceList{
+		scsiBus0,
+		scsiBus1,
+		sataBus2,
+		diskBus0Unit1,
+		diskBus0Unit0,
+		diskBus1Unit0,
+		diskBus2Unit0,
+	}
+
+	out, err := ReadDiskAttrsForDataSource(l, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(out) != 3 {
+		t.Fatalf("expected 3 disks (SCSI bus 1 excluded by scan count of 1), got %d: %+v", len(out), out)
+	}
+
+	wantUUIDs := []string{"uuid-bus0-unit0", "uuid-bus0-unit1", "uuid-bus2-unit0"}
+	for i, want := range wantUUIDs {
+		if got := out[i]["uuid"]; got != want {
+			t.Errorf("disk %d: expected uuid %q, got %q (full: %+v)", i, want, got, out[i])
+		}
+	}
+
+	if got := out[0]["controller_type"]; got != "lsilogic" {
+		t.Errorf("expected first disk's controller_type to be lsilogic, got %v", got)
+	}
+	if got := out[2]["controller_type"]; got != "sata" {
+		t.Errorf("expected third disk's controller_type to be sata, got %v", got)
+	}
+	if got := out[2]["datastore_id"]; got != "datastore-100" {
+		t.Errorf("expected datastore_id to be datastore-100, got %v", got)
+	}
+	if got := out[2]["path"]; got != "[datastore1] vm/disk.vmdk" {
+		t.Errorf("expected path to be set from backing FileName, got %v", got)
+	}
+}
+
+func TestReadDiskAttrsForDataSourceNoScanLimit(t *testing.T) {
+	scsiBus0 := &types.VirtualLsiLogicController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1000},
+				BusNumber:     0,
+			},
+		},
+	}
+	scsiBus1 := &types.VirtualLsiLogicController{
+		VirtualSCSIController: types.VirtualSCSIController{
+			VirtualController: types.VirtualController{
+				VirtualDevice: types.VirtualDevice{Key: 1001},
+				BusNumber:     1,
+			},
+		},
+	}
+	diskBus0 := testDisk(2000, 1000, 0, 10*1024*1024, "uuid-bus0")
+	diskBus1 := testDisk(2001, 1001, 0, 10*1024*1024, "uuid-bus1")
+
+	l := object.VirtualDeviceList{scsiBus0, scsiBus1, diskBus0, diskBus1}
+
+	out, err := ReadDiskAttrsForDataSource(l, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected both disks to be included with a scan count of 2, got %d: %+v", len(out), out)
+	}
+}
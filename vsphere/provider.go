@@ -0,0 +1,16 @@
+package vsphere
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// Provider returns the actual provider instance.
+func Provider() terraform.ResourceProvider {
+	return &schema.Provider{
+		DataSourcesMap: map[string]*schema.Resource{
+			"vsphere_virtual_machine":  dataSourceVSphereVirtualMachine(),
+			"vsphere_virtual_machines": dataSourceVSphereVirtualMachines(),
+		},
+	}
+}